@@ -0,0 +1,204 @@
+// Package discovery resolves go-import style meta tags, the same
+// mechanism "go get" uses to find the VCS and repository URL behind an
+// arbitrary import path such as "golang.org/x/tools" or
+// "gopkg.in/yaml.v2". Results are cached on disk so that repeated
+// lookups of the same import path don't hit the network every time.
+package discovery
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vim-volt/volt/pathutil/internal/diskcache"
+)
+
+// CacheTTL is how long a resolved Result is considered fresh before
+// discovery re-fetches it from the network.
+const CacheTTL = 7 * 24 * time.Hour
+
+const cacheFileName = "discovery.json"
+
+// Result is the outcome of resolving the go-import (and, optionally,
+// go-source) meta tags for an import path.
+type Result struct {
+	RootPath  string // import path prefix that owns the repository
+	VCS       string // "git", "hg", "svn", ...
+	RepoURL   string // repository clone URL
+	SourceURL string // go-source "home" URL template, if present
+}
+
+type cacheEntry struct {
+	Result    Result    `json:"result"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+type cache map[string]cacheEntry
+
+// Resolve returns the go-import discovery result for importPath,
+// consulting (and updating) the on-disk cache rooted at cacheDir
+// ($VOLTPATH/tmp) before falling back to an HTTPS "?go-get=1" request.
+func Resolve(importPath string, cacheDir string) (*Result, error) {
+	c := make(cache)
+	// A corrupt or missing cache is not fatal, just start fresh.
+	_ = diskcache.Load(cacheDir, cacheFileName, &c)
+
+	if entry, ok := c[importPath]; ok && time.Since(entry.FetchedAt) < CacheTTL {
+		result := entry.Result
+		return &result, nil
+	}
+
+	result, err := fetch(importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	c[importPath] = cacheEntry{Result: *result, FetchedAt: time.Now()}
+	// Caching is best-effort; a write failure shouldn't fail Resolve.
+	_ = diskcache.Save(cacheDir, cacheFileName, c)
+	return result, nil
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// fetch requests "https://<importPath>?go-get=1" and parses the
+// go-import (and go-source) meta tags out of the response body.
+func fetch(importPath string) (*Result, error) {
+	url := "https://" + importPath + "?go-get=1"
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %s: %w", importPath, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery: %s: unexpected status %s", importPath, resp.Status)
+	}
+
+	imports, source, err := parseMetaTags(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %s: %w", importPath, err)
+	}
+
+	imp, err := matchImport(importPath, imports)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		RootPath: imp.Prefix,
+		VCS:      imp.VCS,
+		RepoURL:  imp.RepoRoot,
+	}
+	if source != nil && source.Prefix == imp.Prefix {
+		result.SourceURL = source.Home
+	}
+	return result, nil
+}
+
+// matchImport picks the meta tag whose prefix is the longest match for
+// importPath, the same precedence "go get" uses when a page declares
+// meta tags for more than one prefix.
+func matchImport(importPath string, imports []metaImport) (*metaImport, error) {
+	var best *metaImport
+	for i := range imports {
+		imp := imports[i]
+		if imp.Prefix != importPath && !strings.HasPrefix(importPath, imp.Prefix+"/") {
+			continue
+		}
+		if best == nil || len(imp.Prefix) > len(best.Prefix) {
+			best = &imp
+		}
+	}
+	if best == nil {
+		return nil, errors.New("discovery: no matching go-import meta tag for " + importPath)
+	}
+	return best, nil
+}
+
+type metaImport struct {
+	Prefix, VCS, RepoRoot string
+}
+
+type metaSource struct {
+	Prefix, Home string
+}
+
+// charsetReader matches cmd/go's behavior: meta tag pages are plain
+// HTML, almost always declared (or implied) as UTF-8 or ASCII, neither
+// of which need transcoding.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "ascii":
+		return input, nil
+	default:
+		return nil, fmt.Errorf("can't decode XML document using charset %q", charset)
+	}
+}
+
+// parseMetaTags scans the <head> of an HTML document for
+// <meta name="go-import" content="prefix vcs repoURL"> and
+// <meta name="go-source" content="prefix home [...]"> tags, using a
+// lenient xml.Decoder the same way cmd/go does to tolerate HTML that
+// isn't well-formed XML.
+func parseMetaTags(r io.Reader) (imports []metaImport, source *metaSource, err error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	dec.CharsetReader = charsetReader
+
+	for {
+		tok, terr := dec.Token()
+		if terr != nil {
+			if terr == io.EOF {
+				break
+			}
+			if len(imports) > 0 || source != nil {
+				// Tolerate a malformed tail once we've already found
+				// what we came for.
+				break
+			}
+			return nil, nil, terr
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(start.Name.Local, "body") {
+			break
+		}
+		if !strings.EqualFold(start.Name.Local, "meta") {
+			continue
+		}
+		var name, content string
+		for _, attr := range start.Attr {
+			switch strings.ToLower(attr.Name.Local) {
+			case "name":
+				name = attr.Value
+			case "content":
+				content = attr.Value
+			}
+		}
+		switch name {
+		case "go-import":
+			fields := strings.Fields(content)
+			if len(fields) == 3 {
+				imports = append(imports, metaImport{
+					Prefix:   fields[0],
+					VCS:      fields[1],
+					RepoRoot: fields[2],
+				})
+			}
+		case "go-source":
+			fields := strings.Fields(content)
+			if len(fields) >= 2 && source == nil {
+				source = &metaSource{Prefix: fields[0], Home: fields[1]}
+			}
+		}
+	}
+	return imports, source, nil
+}
@@ -0,0 +1,74 @@
+package discovery
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMetaTagsGoImport(t *testing.T) {
+	html := `<!DOCTYPE html>
+<html>
+<head>
+<meta name="go-import" content="golang.org/x/tools git https://go.googlesource.com/tools">
+<meta name="go-source" content="golang.org/x/tools https://github.com/golang/tools https://github.com/golang/tools/tree/master{/dir} https://github.com/golang/tools/blob/master{/dir}/{file}#L{line}">
+</head>
+<body>
+Redirecting...
+</body>
+</html>`
+
+	imports, source, err := parseMetaTags(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("parseMetaTags() error = %v", err)
+	}
+	if len(imports) != 1 {
+		t.Fatalf("len(imports) = %d, want 1", len(imports))
+	}
+	want := metaImport{Prefix: "golang.org/x/tools", VCS: "git", RepoRoot: "https://go.googlesource.com/tools"}
+	if imports[0] != want {
+		t.Errorf("imports[0] = %+v, want %+v", imports[0], want)
+	}
+	if source == nil {
+		t.Fatal("source = nil, want non-nil")
+	}
+	if source.Prefix != "golang.org/x/tools" || source.Home != "https://github.com/golang/tools" {
+		t.Errorf("source = %+v, want Prefix=golang.org/x/tools Home=https://github.com/golang/tools", *source)
+	}
+}
+
+func TestParseMetaTagsNoMatch(t *testing.T) {
+	html := `<html><head><title>nothing here</title></head><body></body></html>`
+	imports, source, err := parseMetaTags(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("parseMetaTags() error = %v", err)
+	}
+	if len(imports) != 0 {
+		t.Errorf("len(imports) = %d, want 0", len(imports))
+	}
+	if source != nil {
+		t.Errorf("source = %+v, want nil", *source)
+	}
+}
+
+func TestMatchImportLongestPrefix(t *testing.T) {
+	imports := []metaImport{
+		{Prefix: "example.com/repo", VCS: "git", RepoRoot: "https://example.com/repo.git"},
+		{Prefix: "example.com/repo/sub", VCS: "git", RepoRoot: "https://example.com/repo-sub.git"},
+	}
+	imp, err := matchImport("example.com/repo/sub/pkg", imports)
+	if err != nil {
+		t.Fatalf("matchImport() error = %v", err)
+	}
+	if imp.Prefix != "example.com/repo/sub" {
+		t.Errorf("imp.Prefix = %q, want %q", imp.Prefix, "example.com/repo/sub")
+	}
+}
+
+func TestMatchImportNoMatch(t *testing.T) {
+	imports := []metaImport{
+		{Prefix: "example.com/other", VCS: "git", RepoRoot: "https://example.com/other.git"},
+	}
+	if _, err := matchImport("example.com/repo", imports); err == nil {
+		t.Fatal("matchImport() error = nil, want error")
+	}
+}
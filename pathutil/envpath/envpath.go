@@ -0,0 +1,152 @@
+// Package envpath maintains a volt-managed PATH fragment, inspired by
+// pathman. Plugins commonly ship helper binaries (language servers,
+// linters, formatters) under a bin/ directory in their repository;
+// volt records those directories here on install/enable and forgets
+// them again on uninstall/disable, so the user never has to edit a
+// shell rc by hand to pick up a plugin's tools.
+package envpath
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vim-volt/volt/pathutil"
+)
+
+const fileName = "PATH"
+
+// File returns (primary VOLTPATH root)/env/PATH, the newline-delimited
+// file that tracks directories volt has added to the managed PATH
+// fragment.
+func File() string {
+	return filepath.Join(pathutil.PrimaryVoltPath(), "env", fileName)
+}
+
+// EnvPathEntries returns the directories currently recorded in
+// env/PATH, in the order they were added. Returns nil if the file
+// doesn't exist yet.
+func EnvPathEntries() ([]string, error) {
+	data, err := ioutil.ReadFile(File())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			entries = append(entries, line)
+		}
+	}
+	return entries, nil
+}
+
+func writeEntries(entries []string) error {
+	file := File()
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, []byte(strings.Join(entries, "\n")+"\n"), 0644)
+}
+
+// AddEnvPath records dir in env/PATH, if it isn't already present.
+func AddEnvPath(dir string) error {
+	entries, err := EnvPathEntries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e == dir {
+			return nil
+		}
+	}
+	return writeEntries(append(entries, dir))
+}
+
+// RemoveEnvPath drops dir from env/PATH, if present.
+func RemoveEnvPath(dir string) error {
+	entries, err := EnvPathEntries()
+	if err != nil {
+		return err
+	}
+	out := entries[:0]
+	for _, e := range entries {
+		if e != dir {
+			out = append(out, e)
+		}
+	}
+	return writeEntries(out)
+}
+
+// RenderShellSnippet returns the line a user can source from their
+// shell rc to put every recorded directory on $PATH. shell must be one
+// of "bash", "zsh", or "fish".
+func RenderShellSnippet(shell string) (string, error) {
+	entries, err := EnvPathEntries()
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+	switch shell {
+	case "bash", "zsh":
+		return fmt.Sprintf("export PATH=\"%s:$PATH\"\n", strings.Join(entries, ":")), nil
+	case "fish":
+		return fmt.Sprintf("set -gx PATH %s $PATH\n", strings.Join(entries, " ")), nil
+	default:
+		return "", fmt.Errorf("envpath: unsupported shell %q", shell)
+	}
+}
+
+// pluginBinCommentPrefix is the plugconf magic comment volt looks for
+// to learn about extra directories (beyond bin/) that should be added
+// to the managed PATH, one per line, e.g.:
+//
+//	" volt bin: scripts/tools
+const pluginBinCommentPrefix = `" volt bin: `
+
+// ReposBinDirs returns every directory volt should AddEnvPath for
+// reposPath on install/enable (and RemoveEnvPath on uninstall/disable):
+// its bin/ directory, plus any directory declared in its plugconf via
+// a `" volt bin: <relative-dir>` magic comment. It searches every
+// VOLTPATH root, since the checkout and plugconf may not live on the
+// primary one.
+func ReposBinDirs(reposPath pathutil.ReposPath) []string {
+	full := pathutil.FullReposPathAll(reposPath)
+
+	var dirs []string
+	if bin := filepath.Join(full, "bin"); pathutil.Exists(bin) {
+		dirs = append(dirs, bin)
+	}
+	for _, rel := range plugconfBinDirs(reposPath) {
+		if dir := filepath.Join(full, rel); pathutil.Exists(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// plugconfBinDirs extracts the relative directories declared by
+// `" volt bin: <relative-dir>` magic comments in reposPath's plugconf.
+func plugconfBinDirs(reposPath pathutil.ReposPath) []string {
+	data, err := ioutil.ReadFile(pathutil.PlugconfAll(reposPath))
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if rel := strings.TrimPrefix(line, pluginBinCommentPrefix); rel != line {
+			if rel = strings.TrimSpace(rel); rel != "" {
+				dirs = append(dirs, rel)
+			}
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,107 @@
+package envpath
+
+import (
+	"os"
+	"testing"
+)
+
+func withVoltPath(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	old, hadOld := os.LookupEnv("VOLTPATH")
+	os.Setenv("VOLTPATH", dir)
+	t.Cleanup(func() {
+		if hadOld {
+			os.Setenv("VOLTPATH", old)
+		} else {
+			os.Unsetenv("VOLTPATH")
+		}
+	})
+	return dir
+}
+
+func TestAddRemoveEnvPath(t *testing.T) {
+	withVoltPath(t)
+
+	if err := AddEnvPath("/opt/plugin-a/bin"); err != nil {
+		t.Fatalf("AddEnvPath() error = %v", err)
+	}
+	if err := AddEnvPath("/opt/plugin-b/bin"); err != nil {
+		t.Fatalf("AddEnvPath() error = %v", err)
+	}
+	// Adding the same directory twice must not duplicate it.
+	if err := AddEnvPath("/opt/plugin-a/bin"); err != nil {
+		t.Fatalf("AddEnvPath() error = %v", err)
+	}
+
+	entries, err := EnvPathEntries()
+	if err != nil {
+		t.Fatalf("EnvPathEntries() error = %v", err)
+	}
+	want := []string{"/opt/plugin-a/bin", "/opt/plugin-b/bin"}
+	if !equal(entries, want) {
+		t.Fatalf("EnvPathEntries() = %v, want %v", entries, want)
+	}
+
+	if err := RemoveEnvPath("/opt/plugin-a/bin"); err != nil {
+		t.Fatalf("RemoveEnvPath() error = %v", err)
+	}
+	entries, err = EnvPathEntries()
+	if err != nil {
+		t.Fatalf("EnvPathEntries() error = %v", err)
+	}
+	want = []string{"/opt/plugin-b/bin"}
+	if !equal(entries, want) {
+		t.Fatalf("EnvPathEntries() after remove = %v, want %v", entries, want)
+	}
+}
+
+func TestEnvPathEntriesMissingFile(t *testing.T) {
+	withVoltPath(t)
+	entries, err := EnvPathEntries()
+	if err != nil {
+		t.Fatalf("EnvPathEntries() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("EnvPathEntries() = %v, want empty", entries)
+	}
+}
+
+func TestRenderShellSnippet(t *testing.T) {
+	withVoltPath(t)
+	if err := AddEnvPath("/opt/plugin-a/bin"); err != nil {
+		t.Fatalf("AddEnvPath() error = %v", err)
+	}
+
+	bash, err := RenderShellSnippet("bash")
+	if err != nil {
+		t.Fatalf("RenderShellSnippet(bash) error = %v", err)
+	}
+	if want := "export PATH=\"/opt/plugin-a/bin:$PATH\"\n"; bash != want {
+		t.Errorf("RenderShellSnippet(bash) = %q, want %q", bash, want)
+	}
+
+	fish, err := RenderShellSnippet("fish")
+	if err != nil {
+		t.Fatalf("RenderShellSnippet(fish) error = %v", err)
+	}
+	if want := "set -gx PATH /opt/plugin-a/bin $PATH\n"; fish != want {
+		t.Errorf("RenderShellSnippet(fish) = %q, want %q", fish, want)
+	}
+
+	if _, err := RenderShellSnippet("powershell"); err == nil {
+		t.Error("RenderShellSnippet(powershell) error = nil, want error")
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package envpath
+
+import "errors"
+
+// WriteRegistryPath is only meaningful on Windows, where there is no
+// shell rc to source and $PATH instead comes from the registry.
+func WriteRegistryPath() error {
+	return errors.New("envpath: registry PATH is only supported on Windows")
+}
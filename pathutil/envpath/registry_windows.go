@@ -0,0 +1,53 @@
+//go:build windows
+// +build windows
+
+package envpath
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// WriteRegistryPath writes the joined env/PATH entries into
+// HKCU\Environment, the same key the Windows "Environment Variables"
+// control panel edits, so new terminals pick up plugin-provided tools
+// without the user touching a shell rc.
+func WriteRegistryPath() error {
+	entries, err := EnvPathEntries()
+	if err != nil {
+		return err
+	}
+	key, err := registry.OpenKey(registry.CURRENT_USER, "Environment", registry.SET_VALUE|registry.QUERY_VALUE)
+	if err != nil {
+		return fmt.Errorf("envpath: opening HKCU\\Environment: %w", err)
+	}
+	defer key.Close()
+
+	existing, _, err := key.GetStringValue("Path")
+	if err != nil && err != registry.ErrNotExist {
+		return fmt.Errorf("envpath: reading current Path: %w", err)
+	}
+	return key.SetStringValue("Path", mergePath(existing, entries))
+}
+
+// mergePath appends entries not already present in existing (a
+// ";"-joined Windows PATH value), preserving existing's order.
+func mergePath(existing string, entries []string) string {
+	have := make(map[string]bool)
+	var parts []string
+	if existing != "" {
+		parts = strings.Split(existing, ";")
+		for _, p := range parts {
+			have[p] = true
+		}
+	}
+	for _, e := range entries {
+		if !have[e] {
+			parts = append(parts, e)
+			have[e] = true
+		}
+	}
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,39 @@
+// Package diskcache is the shared on-disk JSON cache pattern used by
+// pathutil subsystems (discovery, pathutil's own VCSFor, vimexe) that memoize the result
+// of a slow probe (an HTTP fetch, a VCS ping, a vim --version exec)
+// under $VOLTPATH/tmp. It only does the read/write; callers own their
+// own cache entry type and expiry check.
+package diskcache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Load reads the JSON file at filepath.Join(dir, name) into v. Callers
+// should treat any returned error (missing file, corrupt JSON) as "no
+// cache yet" and start fresh rather than failing outright.
+func Load(dir, name string, v interface{}) error {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Save creates dir if needed and writes v as indented JSON to
+// filepath.Join(dir, name). Caching is best-effort: callers typically
+// ignore the returned error rather than fail their primary operation
+// over a cache write failure.
+func Save(dir, name string, v interface{}) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, name), data, 0644)
+}
@@ -7,28 +7,77 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
+
+	"github.com/vim-volt/volt/pathutil/discovery"
+	"github.com/vim-volt/volt/pathutil/internal/diskcache"
+	"github.com/vim-volt/volt/pathutil/vcs"
 )
 
 // Normalize the following forms into "github.com/user/name":
-// 1. user/name[.git]
-// 2. github.com/user/name[.git]
-// 3. [git|http|https]://github.com/user/name[.git]
+//  1. user/name[.git]
+//  2. github.com/user/name[.git]
+//  3. [git|http|https]://github.com/user/name[.git]
+//  4. vcs+scheme://host/path[.git] (e.g. "hg+https://bitbucket.org/user/name"),
+//     normalized to "host/path" rather than "github.com/user/name"
+//  5. Arbitrary go-import style host path (e.g. "golang.org/x/tools",
+//     "gopkg.in/yaml.v2"), normalized to the go-import root that
+//     pathutil/discovery resolves for it
 func NormalizeRepos(rawReposPath string) (ReposPath, error) {
 	rawReposPath = filepath.ToSlash(rawReposPath)
+	if cmd, _, rest, ok := vcs.ParsePrefix(rawReposPath); ok {
+		reposPath := ReposPath(strings.TrimSuffix(rest, ".git"))
+		recordForcedVCS(reposPath, cmd)
+		return reposPath, nil
+	}
 	paths := strings.Split(rawReposPath, "/")
-	if len(paths) == 3 {
+	if len(paths) == 2 {
+		if !strings.Contains(paths[0], ".") {
+			return ReposPath(strings.TrimSuffix("github.com/"+rawReposPath, ".git")), nil
+		}
+		// e.g. "gopkg.in/yaml.v2": the first segment already looks like
+		// a host, so let go-import discovery find the real module root
+		// instead of assuming it's a github.com/user/name shortcut.
+		if repos, err := discoverRepos(rawReposPath); err == nil {
+			return repos, nil
+		}
 		return ReposPath(strings.TrimSuffix(rawReposPath, ".git")), nil
 	}
-	if len(paths) == 2 {
-		return ReposPath(strings.TrimSuffix("github.com/"+rawReposPath, ".git")), nil
+	if len(paths) == 3 {
+		if strings.Contains(paths[0], ".") {
+			// e.g. "cloud.google.com/go/storage": a subpackage of a
+			// shared multi-package repo whose real go-import root may
+			// be shorter than the literal input. Let discovery find it
+			// instead of assuming this 3-segment path is its own root.
+			if repos, err := discoverRepos(rawReposPath); err == nil {
+				return repos, nil
+			}
+		}
+		return ReposPath(strings.TrimSuffix(rawReposPath, ".git")), nil
 	}
 	if paths[0] == "https:" || paths[0] == "http:" || paths[0] == "git:" {
 		path := strings.Join(paths[len(paths)-3:], "/")
 		return ReposPath(strings.TrimSuffix(path, ".git")), nil
 	}
+	// A deeper vanity import path (e.g. "golang.org/x/tools/cmd/godoc").
+	// Ask go-import discovery which prefix actually owns the repository.
+	if repos, err := discoverRepos(rawReposPath); err == nil {
+		return repos, nil
+	}
 	return ReposPath(""), errors.New("invalid format of repository: " + rawReposPath)
 }
 
+// discoverRepos resolves rawReposPath via the go-import meta tag served
+// at "https://<rawReposPath>?go-get=1" and returns its module root as a
+// ReposPath.
+func discoverRepos(rawReposPath string) (ReposPath, error) {
+	result, err := discovery.Resolve(rawReposPath, TempDir())
+	if err != nil {
+		return ReposPath(""), err
+	}
+	return ReposPath(strings.TrimSuffix(result.RootPath, ".git")), nil
+}
+
 type ReposPath string
 type ReposPathList []ReposPath
 
@@ -70,38 +119,242 @@ func HomeDir() string {
 	panic("Couldn't look up HOME")
 }
 
+// voltPathListSeparator splits a $VOLTPATH value carrying more than one
+// root, the same way $PATH is split: ":" on Unix, ";" on Windows.
+const voltPathListSeparator = string(os.PathListSeparator)
+
+// VoltPaths returns every root configured in $VOLTPATH, in order.
+// Following ghq's GHQ_ROOT, $VOLTPATH may list more than one root so
+// users can keep large third-party plugins on one disk and
+// personal/local plugins on another.
+func VoltPaths() []string {
+	env := os.Getenv("VOLTPATH")
+	var paths []string
+	for _, p := range strings.Split(env, voltPathListSeparator) {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return []string{filepath.Join(HomeDir(), "volt")}
+	}
+	return paths
+}
+
+// PrimaryVoltPath is the first root of VoltPaths(). New clones,
+// lock.json, config.toml, and trx.lock always live under it.
+func PrimaryVoltPath() string {
+	return VoltPaths()[0]
+}
+
 // $HOME/volt
 func VoltPath() string {
-	path := os.Getenv("VOLTPATH")
-	if path != "" {
-		return path
-	}
-	return filepath.Join(HomeDir(), "volt")
+	return PrimaryVoltPath()
 }
 
-func FullReposPath(reposPath ReposPath) string {
+func fullReposPathIn(root string, reposPath ReposPath) string {
 	reposList := strings.Split(filepath.ToSlash(reposPath.String()), "/")
 	paths := make([]string, 0, len(reposList)+2)
-	paths = append(paths, VoltPath())
+	paths = append(paths, root)
 	paths = append(paths, "repos")
 	paths = append(paths, reposList...)
 	return filepath.Join(paths...)
 }
 
-// https://{reposPath}
+// FullReposPath returns reposPath's checkout path under the primary
+// VOLTPATH root. Use FullReposPathAll to search every root.
+func FullReposPath(reposPath ReposPath) string {
+	return fullReposPathIn(PrimaryVoltPath(), reposPath)
+}
+
+// FullReposPathAll is like FullReposPath, but searches every VOLTPATH
+// root for an existing checkout before falling back to the primary
+// root's path (e.g. when reposPath is about to be cloned for the first
+// time).
+func FullReposPathAll(reposPath ReposPath) string {
+	if root, ok := LocateReposPath(reposPath); ok {
+		return fullReposPathIn(root, reposPath)
+	}
+	return FullReposPath(reposPath)
+}
+
+// LocateReposPath walks every VOLTPATH root and returns the first one
+// that has reposPath checked out, and whether any did.
+func LocateReposPath(reposPath ReposPath) (string, bool) {
+	for _, root := range VoltPaths() {
+		if Exists(fullReposPathIn(root, reposPath)) {
+			return root, true
+		}
+	}
+	return "", false
+}
+
+// ReposPathFromFullPath is the inverse of FullReposPath(All): given an
+// absolute path under any VOLTPATH root's "repos" directory, it strips
+// the matching "<root>/repos/" prefix and returns the corresponding
+// ReposPath. ok is false when fullPath isn't under any root.
+func ReposPathFromFullPath(fullPath string) (reposPath ReposPath, ok bool) {
+	fullPath = filepath.ToSlash(fullPath)
+	for _, root := range VoltPaths() {
+		prefix := filepath.ToSlash(filepath.Join(root, "repos")) + "/"
+		if strings.HasPrefix(fullPath, prefix) {
+			return ReposPath(strings.TrimPrefix(fullPath, prefix)), true
+		}
+	}
+	return ReposPath(""), false
+}
+
+// CloneURL returns the URL used to clone reposPath. For a known
+// unambiguous host (github.com, gitlab.com) it returns the historical
+// "https://{reposPath}" guess directly, without touching the network.
+// Otherwise it consults the go-import discovery result when one is
+// available (e.g. a Mercurial repository hosted at a path that doesn't
+// map 1:1 to an HTTPS URL); failing that it asks VCSFor (cached) which
+// backend manages reposPath and uses that backend's preferred scheme,
+// falling back to "https://{reposPath}" if nothing answers.
 func CloneURL(reposPath ReposPath) string {
-	return "https://" + filepath.ToSlash(reposPath.String())
+	repo := filepath.ToSlash(reposPath.String())
+	if host := strings.SplitN(repo, "/", 2)[0]; knownGitHosts[host] {
+		return "https://" + repo
+	}
+	if result, err := discovery.Resolve(repo, TempDir()); err == nil && result.RepoURL != "" {
+		return result.RepoURL
+	}
+	if cmd, err := VCSFor(reposPath); err == nil {
+		if scheme, err := vcs.PreferredScheme(cmd, repo); err == nil {
+			return scheme + "://" + repo
+		}
+	}
+	return "https://" + repo
 }
 
-func Plugconf(reposPath ReposPath) string {
+// knownGitHosts are hosts that are unambiguously git, so VCSFor can
+// skip both the network (discovery) and probing (vcs.PreferredScheme)
+// for the overwhelming majority of plugins, which live on one of these.
+var knownGitHosts = map[string]bool{
+	"github.com": true,
+	"gitlab.com": true,
+}
+
+const vcsResolveCacheFile = "vcs-resolve.json"
+
+type vcsCacheEntry struct {
+	VCS       string    `json:"vcs"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// VCSFor determines which VCS backend manages reposPath for cloning.
+// It tries, in order: a known unambiguous host (github.com, gitlab.com)
+// skipping the network entirely; a cached earlier resolution for
+// reposPath; the go-import discovery result's VCS field; and finally
+// probing each registered VCS's preferred scheme, defaulting to git
+// when nothing answers. Anything beyond the known-host check is cached
+// at (primary VOLTPATH root)/tmp/vcs-resolve.json for discovery.CacheTTL
+// so repeated calls (e.g. over a whole plugin list) don't re-probe.
+func VCSFor(reposPath ReposPath) (*vcs.Cmd, error) {
+	repo := filepath.ToSlash(reposPath.String())
+	if host := strings.SplitN(repo, "/", 2)[0]; knownGitHosts[host] {
+		return vcs.Git, nil
+	}
+
+	cacheDir := TempDir()
+	cache := loadVCSCache(cacheDir)
+	if entry, ok := cache[repo]; ok && time.Since(entry.FetchedAt) < discovery.CacheTTL {
+		if cmd, ok := vcs.ByName(entry.VCS); ok {
+			return cmd, nil
+		}
+	}
+
+	cmd, err := resolveVCS(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	cache[repo] = vcsCacheEntry{VCS: cmd.Name, FetchedAt: time.Now()}
+	_ = diskcache.Save(cacheDir, vcsResolveCacheFile, cache)
+	return cmd, nil
+}
+
+// resolveVCS does the actual (uncached) work for VCSFor.
+func resolveVCS(repo string) (*vcs.Cmd, error) {
+	if result, err := discovery.Resolve(repo, TempDir()); err == nil && result.VCS != "" {
+		if cmd, ok := vcs.ByName(result.VCS); ok {
+			return cmd, nil
+		}
+	}
+	for _, cmd := range []*vcs.Cmd{vcs.Git, vcs.Hg, vcs.Svn} {
+		if _, err := vcs.PreferredScheme(cmd, repo); err == nil {
+			return cmd, nil
+		}
+	}
+	return vcs.Git, nil
+}
+
+func loadVCSCache(cacheDir string) map[string]vcsCacheEntry {
+	cache := make(map[string]vcsCacheEntry)
+	_ = diskcache.Load(cacheDir, vcsResolveCacheFile, &cache)
+	return cache
+}
+
+// recordForcedVCS persists cmd as reposPath's resolved VCS, the same
+// cache VCSFor consults, so a "vcs+scheme://" prefix NormalizeRepos
+// parsed off the user's input (e.g. "hg+https://bitbucket.org/user/name")
+// is actually honored later instead of being re-guessed from scratch via
+// discovery/ping-probing. Caching is best-effort; a write failure just
+// means VCSFor falls back to resolving reposPath on its own.
+func recordForcedVCS(reposPath ReposPath, cmd *vcs.Cmd) {
+	repo := filepath.ToSlash(reposPath.String())
+	cacheDir := TempDir()
+	cache := loadVCSCache(cacheDir)
+	cache[repo] = vcsCacheEntry{VCS: cmd.Name, FetchedAt: time.Now()}
+	_ = diskcache.Save(cacheDir, vcsResolveCacheFile, cache)
+}
+
+// DetectVCS walks up from reposPath's local checkout looking for a VCS
+// working-copy marker (.git, .hg, .svn), so volt can decide how to
+// pull/checkout a repository it didn't clone itself. It searches every
+// VOLTPATH root (via FullReposPathAll), since the checkout may not live
+// on the primary one.
+func DetectVCS(reposPath ReposPath) (*vcs.Cmd, error) {
+	return vcs.DetectVCS(FullReposPathAll(reposPath))
+}
+
+// SourceURL returns the go-source "home" URL for reposPath, as declared
+// by a <meta name="go-source"> tag, or "" if discovery found none.
+func SourceURL(reposPath ReposPath) string {
+	result, err := discovery.Resolve(reposPath.String(), TempDir())
+	if err != nil {
+		return ""
+	}
+	return result.SourceURL
+}
+
+func plugconfIn(root string, reposPath ReposPath) string {
 	filenameList := strings.Split(filepath.ToSlash(reposPath.String()+".vim"), "/")
 	paths := make([]string, 0, len(filenameList)+2)
-	paths = append(paths, VoltPath())
+	paths = append(paths, root)
 	paths = append(paths, "plugconf")
 	paths = append(paths, filenameList...)
 	return filepath.Join(paths...)
 }
 
+// Plugconf returns reposPath's plugconf path under the primary VOLTPATH
+// root. Use PlugconfAll to search every root.
+func Plugconf(reposPath ReposPath) string {
+	return plugconfIn(PrimaryVoltPath(), reposPath)
+}
+
+// PlugconfAll is like Plugconf, but searches every VOLTPATH root for an
+// existing plugconf file before falling back to the primary root's path.
+func PlugconfAll(reposPath ReposPath) string {
+	for _, root := range VoltPaths() {
+		if p := plugconfIn(root, reposPath); Exists(p) {
+			return p
+		}
+	}
+	return Plugconf(reposPath)
+}
+
 const ProfileVimrc = "vimrc.vim"
 const ProfileGvimrc = "gvimrc.vim"
 const Vimrc = "vimrc"
@@ -109,7 +362,19 @@ const Gvimrc = "gvimrc"
 
 // $HOME/volt/rc/{profileName}
 func RCDir(profileName string) string {
-	return filepath.Join([]string{VoltPath(), "rc", profileName}...)
+	return filepath.Join(PrimaryVoltPath(), "rc", profileName)
+}
+
+// RCDirAll is like RCDir, but searches every VOLTPATH root for an
+// existing profile directory before falling back to the primary root's
+// path.
+func RCDirAll(profileName string) string {
+	for _, root := range VoltPaths() {
+		if dir := filepath.Join(root, "rc", profileName); Exists(dir) {
+			return dir
+		}
+	}
+	return RCDir(profileName)
 }
 
 var packer = strings.NewReplacer("_", "__", "/", "_")
@@ -123,6 +388,14 @@ func EncodeReposPath(reposPath ReposPath) string {
 	return filepath.Join(VimVoltOptDir(), path)
 }
 
+// EncodeReposPathAll exists for symmetry with FullReposPathAll and
+// PlugconfAll. The encoded directory lives under VimDir(), not a
+// VOLTPATH root, so there is nothing to search across roots for: it
+// always returns EncodeReposPath(reposPath).
+func EncodeReposPathAll(reposPath ReposPath) string {
+	return EncodeReposPath(reposPath)
+}
+
 // Decode name to repos path.
 // name is directory name: ~/.vim/pack/volt/opt/{name}
 func DecodeReposPath(name string) ReposPath {
@@ -130,29 +403,31 @@ func DecodeReposPath(name string) ReposPath {
 	return ReposPath(unpacker2.Replace(unpacker1.Replace(name)))
 }
 
-// $HOME/volt/lock.json
+// (primary VOLTPATH root)/lock.json
 func LockJSON() string {
-	return filepath.Join(VoltPath(), "lock.json")
+	return filepath.Join(PrimaryVoltPath(), "lock.json")
 }
 
-// $HOME/volt/config.toml
+// (primary VOLTPATH root)/config.toml
 func ConfigTOML() string {
-	return filepath.Join(VoltPath(), "config.toml")
+	return filepath.Join(PrimaryVoltPath(), "config.toml")
 }
 
-// $HOME/volt/trx.lock
+// (primary VOLTPATH root)/trx.lock
 func TrxLock() string {
-	return filepath.Join(VoltPath(), "trx.lock")
+	return filepath.Join(PrimaryVoltPath(), "trx.lock")
 }
 
-// $HOME/tmp
+// (primary VOLTPATH root)/tmp
 func TempDir() string {
-	return filepath.Join(VoltPath(), "tmp")
+	return filepath.Join(PrimaryVoltPath(), "tmp")
 }
 
 // Detect vim executable path.
 // If VOLT_VIM environment variable is set, use it.
 // Otherwise look up "vim" binary from PATH.
+// See pathutil/vimexe for flavor selection, version probing, and
+// has()-style feature gates.
 func VimExecutable() (string, error) {
 	var vim string
 	if vim = os.Getenv("VOLT_VIM"); vim != "" {
@@ -228,6 +503,14 @@ func LookUpVimrc() []string {
 	return vimrcPaths
 }
 
+// LookUpVimrcAll exists for symmetry with FullReposPathAll and
+// PlugconfAll. Vimrc candidates live under $HOME and VimDir(), not a
+// VOLTPATH root, so there is nothing to search across roots for: it
+// always returns LookUpVimrc().
+func LookUpVimrcAll() []string {
+	return LookUpVimrc()
+}
+
 // Look up gvimrc path from the following candidates:
 //   Windows  : $HOME/_gvimrc
 //              (vim dir)/gvimrc
@@ -0,0 +1,103 @@
+package pathutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeRepos(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"user/name", "github.com/user/name"},
+		{"user/name.git", "github.com/user/name"},
+		{"github.com/user/name", "github.com/user/name"},
+		{"https://github.com/user/name.git", "github.com/user/name"},
+		{"http://github.com/user/name", "github.com/user/name"},
+		{"git://github.com/user/name", "github.com/user/name"},
+		// A 3-segment path whose first segment isn't host-shaped (no
+		// "."): not ambiguous with a vanity import path, so it's taken
+		// literally without consulting discovery.
+		{"user/name/sub", "user/name/sub"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeRepos(tt.in)
+		if err != nil {
+			t.Errorf("NormalizeRepos(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("NormalizeRepos(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeReposForcedVCSHonoredByVCSFor(t *testing.T) {
+	t.Setenv("VOLTPATH", t.TempDir())
+
+	reposPath, err := NormalizeRepos("hg+https://bitbucket.org/user/name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ReposPath("bitbucket.org/user/name"); reposPath != want {
+		t.Fatalf("NormalizeRepos(...) = %q, want %q", reposPath, want)
+	}
+
+	// VCSFor must honor the "hg+" prefix NormalizeRepos parsed off,
+	// without re-probing the network to guess a backend.
+	cmd, err := VCSFor(reposPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Name != "hg" {
+		t.Errorf("VCSFor(%q) = %q, want %q", reposPath, cmd.Name, "hg")
+	}
+}
+
+func TestVoltPathsDefault(t *testing.T) {
+	t.Setenv("VOLTPATH", "")
+	t.Setenv("HOME", "/home/tester")
+	paths := VoltPaths()
+	want := filepath.Join("/home/tester", "volt")
+	if len(paths) != 1 || paths[0] != want {
+		t.Errorf("VoltPaths() = %v, want [%s]", paths, want)
+	}
+}
+
+func TestVoltPathsMultiRoot(t *testing.T) {
+	root1, root2 := t.TempDir(), t.TempDir()
+	t.Setenv("VOLTPATH", root1+voltPathListSeparator+root2)
+
+	paths := VoltPaths()
+	if len(paths) != 2 || paths[0] != root1 || paths[1] != root2 {
+		t.Errorf("VoltPaths() = %v, want [%s %s]", paths, root1, root2)
+	}
+	if PrimaryVoltPath() != root1 {
+		t.Errorf("PrimaryVoltPath() = %q, want %q", PrimaryVoltPath(), root1)
+	}
+}
+
+func TestLocateReposPathAndFromFullPath(t *testing.T) {
+	root1, root2 := t.TempDir(), t.TempDir()
+	t.Setenv("VOLTPATH", root1+voltPathListSeparator+root2)
+
+	reposPath := ReposPath("github.com/user/name")
+	full := fullReposPathIn(root2, reposPath)
+	if err := os.MkdirAll(full, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	root, ok := LocateReposPath(reposPath)
+	if !ok || root != root2 {
+		t.Errorf("LocateReposPath(%q) = (%q, %v), want (%q, true)", reposPath, root, ok, root2)
+	}
+	if got := FullReposPathAll(reposPath); got != full {
+		t.Errorf("FullReposPathAll(%q) = %q, want %q", reposPath, got, full)
+	}
+
+	got, ok := ReposPathFromFullPath(full)
+	if !ok || got != reposPath {
+		t.Errorf("ReposPathFromFullPath(%q) = (%q, %v), want (%q, true)", full, got, ok, reposPath)
+	}
+}
@@ -0,0 +1,201 @@
+// Package vcs describes the version control systems volt knows how to
+// drive, modeled on cmd/go/internal/vcs. It lets volt manage plugins
+// that live in git, Mercurial, or Subversion repositories instead of
+// assuming every plugin is git-over-https.
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Cmd describes how to drive a particular version control system.
+type Cmd struct {
+	Name string // "git", "hg", "svn"
+	Cmd  string // name of the binary to exec
+
+	// RootNames are directory entries that mark the root of a working
+	// copy managed by this VCS (".git", ".hg", ".svn").
+	RootNames []string
+
+	// CreateCmd clones repo into dir for the first time.
+	CreateCmd []string
+	// DownloadCmd updates an existing working copy in dir.
+	DownloadCmd []string
+	// TagCmd lists the tags of the working copy in dir.
+	TagCmd []string
+	// TagSyncCmd checks out {tag} in the working copy in dir.
+	TagSyncCmd []string
+
+	// Schemes lists the URL schemes this VCS can be reached over, in
+	// the order they should be tried when no scheme was specified.
+	Schemes []string
+	// PingCmd probes whether a {scheme}://{repo} remote answers.
+	PingCmd []string
+}
+
+// Registered VCS backends.
+var (
+	Git = &Cmd{
+		Name:        "git",
+		Cmd:         "git",
+		RootNames:   []string{".git"},
+		CreateCmd:   []string{"clone", "{repo}", "{dir}"},
+		DownloadCmd: []string{"pull"},
+		TagCmd:      []string{"tag", "-l"},
+		TagSyncCmd:  []string{"checkout", "{tag}"},
+		Schemes:     []string{"https", "http", "git", "ssh"},
+		PingCmd:     []string{"ls-remote", "{scheme}://{repo}"},
+	}
+	Hg = &Cmd{
+		Name:        "hg",
+		Cmd:         "hg",
+		RootNames:   []string{".hg"},
+		CreateCmd:   []string{"clone", "{repo}", "{dir}"},
+		DownloadCmd: []string{"pull"},
+		TagCmd:      []string{"tags"},
+		TagSyncCmd:  []string{"update", "{tag}"},
+		Schemes:     []string{"https", "http", "ssh"},
+		PingCmd:     []string{"identify", "{scheme}://{repo}"},
+	}
+	Svn = &Cmd{
+		Name:        "svn",
+		Cmd:         "svn",
+		RootNames:   []string{".svn"},
+		CreateCmd:   []string{"checkout", "{repo}", "{dir}"},
+		DownloadCmd: []string{"update"},
+		TagCmd:      []string{"list", "^/tags"},
+		TagSyncCmd:  []string{"switch", "^/tags/{tag}"},
+		Schemes:     []string{"https", "http", "svn"},
+		PingCmd:     []string{"info", "{scheme}://{repo}"},
+	}
+)
+
+// byName is the registry of supported VCSes, keyed by Cmd.Name.
+var byName = map[string]*Cmd{
+	Git.Name: Git,
+	Hg.Name:  Hg,
+	Svn.Name: Svn,
+}
+
+// ByName looks up a registered VCS by name ("git", "hg", "svn").
+func ByName(name string) (*Cmd, bool) {
+	cmd, ok := byName[name]
+	return cmd, ok
+}
+
+// ParsePrefix splits an explicit "vcs+scheme://host/path" prefix off
+// rawReposPath (e.g. "hg+https://bitbucket.org/user/name"), returning
+// the matched Cmd, the scheme, and the remaining host/path. ok is false
+// when rawReposPath carries no such prefix.
+func ParsePrefix(rawReposPath string) (cmd *Cmd, scheme, rest string, ok bool) {
+	i := strings.Index(rawReposPath, "+")
+	j := strings.Index(rawReposPath, "://")
+	if i < 0 || j < 0 || j < i {
+		return nil, "", "", false
+	}
+	c, found := ByName(rawReposPath[:i])
+	if !found {
+		return nil, "", "", false
+	}
+	return c, rawReposPath[i+1 : j], rawReposPath[j+3:], true
+}
+
+// Ping reports whether repo answers over scheme for cmd's VCS, by
+// running cmd.PingCmd with a short timeout.
+func Ping(cmd *Cmd, scheme, repo string) error {
+	if len(cmd.PingCmd) == 0 {
+		return fmt.Errorf("vcs: %s has no ping command", cmd.Name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	args := expand(cmd.PingCmd, map[string]string{"scheme": scheme, "repo": repo})
+	return exec.CommandContext(ctx, cmd.Cmd, args...).Run()
+}
+
+// PreferredScheme pings cmd's Schemes in order and returns the first
+// one that answers for repo.
+func PreferredScheme(cmd *Cmd, repo string) (string, error) {
+	var lastErr error
+	for _, scheme := range cmd.Schemes {
+		if err := Ping(cmd, scheme, repo); err == nil {
+			return scheme, nil
+		} else {
+			lastErr = err
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no schemes configured")
+	}
+	return "", fmt.Errorf("vcs: no scheme of %s answered for %s: %w", cmd.Name, repo, lastErr)
+}
+
+// DetectVCS walks up from fullReposPath looking for a RootNames marker
+// (".git", ".hg", ".svn", ...), so volt can tell how to pull/checkout a
+// repository it didn't clone itself.
+func DetectVCS(fullReposPath string) (*Cmd, error) {
+	dir := fullReposPath
+	for {
+		for _, cmd := range byName {
+			for _, root := range cmd.RootNames {
+				if _, err := os.Stat(filepath.Join(dir, root)); err == nil {
+					return cmd, nil
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return nil, fmt.Errorf("vcs: could not detect VCS under %s", fullReposPath)
+}
+
+// Create runs cmd's CreateCmd to clone repo into dir for the first time.
+func Create(cmd *Cmd, dir, repo string) error {
+	vars := map[string]string{"repo": repo, "dir": dir}
+	return run(cmd, filepath.Dir(dir), cmd.CreateCmd, vars)
+}
+
+// Download runs cmd's DownloadCmd to update the existing working copy
+// in dir.
+func Download(cmd *Cmd, dir string) error {
+	return run(cmd, dir, cmd.DownloadCmd, map[string]string{"dir": dir})
+}
+
+// TagSync runs cmd's TagSyncCmd to check out tag in the working copy in
+// dir.
+func TagSync(cmd *Cmd, dir, tag string) error {
+	return run(cmd, dir, cmd.TagSyncCmd, map[string]string{"dir": dir, "tag": tag})
+}
+
+// expand substitutes {key} placeholders in template with values from
+// vars.
+func expand(template []string, vars map[string]string) []string {
+	args := make([]string, len(template))
+	for i, tok := range template {
+		for key, val := range vars {
+			tok = strings.ReplaceAll(tok, "{"+key+"}", val)
+		}
+		args[i] = tok
+	}
+	return args
+}
+
+func run(cmd *Cmd, workDir string, template []string, vars map[string]string) error {
+	if len(template) == 0 {
+		return fmt.Errorf("vcs: %s has no command for this operation", cmd.Name)
+	}
+	c := exec.Command(cmd.Cmd, expand(template, vars)...)
+	c.Dir = workDir
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
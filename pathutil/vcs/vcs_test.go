@@ -0,0 +1,60 @@
+package vcs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		in         string
+		wantCmd    string
+		wantScheme string
+		wantRest   string
+		wantOK     bool
+	}{
+		{"hg+https://bitbucket.org/user/name", "hg", "https", "bitbucket.org/user/name", true},
+		{"svn+http://example.com/repo", "svn", "http", "example.com/repo", true},
+		{"https://github.com/user/name", "", "", "", false},
+		{"unknown+https://example.com/repo", "", "", "", false},
+		{"user/name", "", "", "", false},
+	}
+	for _, tt := range tests {
+		cmd, scheme, rest, ok := ParsePrefix(tt.in)
+		if ok != tt.wantOK {
+			t.Errorf("ParsePrefix(%q) ok = %v, want %v", tt.in, ok, tt.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if cmd.Name != tt.wantCmd || scheme != tt.wantScheme || rest != tt.wantRest {
+			t.Errorf("ParsePrefix(%q) = (%q, %q, %q), want (%q, %q, %q)",
+				tt.in, cmd.Name, scheme, rest, tt.wantCmd, tt.wantScheme, tt.wantRest)
+		}
+	}
+}
+
+func TestDetectVCS(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "user", "name")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "user", ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err := DetectVCS(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Name != "git" {
+		t.Errorf("DetectVCS(%q) = %q, want %q", nested, cmd.Name, "git")
+	}
+
+	if _, err := DetectVCS(t.TempDir()); err == nil {
+		t.Error("DetectVCS on a directory with no VCS marker: want error, got nil")
+	}
+}
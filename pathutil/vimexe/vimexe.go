@@ -0,0 +1,267 @@
+// Package vimexe resolves and probes the vim-family executable volt
+// should drive: plain vim, Neovim, GVim, or MacVim. It caches each
+// binary's probed version and feature set so plugconf gates like
+// `if has('nvim-0.9')` can be evaluated at plan-time, telling the user
+// up-front which plugins won't load instead of failing at runtime.
+package vimexe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vim-volt/volt/pathutil"
+	"github.com/vim-volt/volt/pathutil/internal/diskcache"
+)
+
+// Flavor identifies which vim-family program to drive.
+type Flavor string
+
+const (
+	Vim    Flavor = "vim"
+	Neovim Flavor = "nvim"
+	GVim   Flavor = "gvim"
+	MacVim Flavor = "macvim"
+)
+
+var exeNames = map[Flavor]string{
+	Vim:    "vim",
+	Neovim: "nvim",
+	GVim:   "gvim",
+	MacVim: "mvim",
+}
+
+// CurrentFlavor returns the flavor volt should use: $VOLT_VIM_FLAVOR
+// when set, otherwise Vim.
+func CurrentFlavor() Flavor {
+	if f := os.Getenv("VOLT_VIM_FLAVOR"); f != "" {
+		return Flavor(f)
+	}
+	return Vim
+}
+
+// ExecutableName returns flavor's binary name, adding ".exe" on Windows.
+func ExecutableName(flavor Flavor) (string, error) {
+	name, ok := exeNames[flavor]
+	if !ok {
+		return "", fmt.Errorf("vimexe: unknown vim flavor %q", flavor)
+	}
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name, nil
+}
+
+// Executable resolves the path to flavor's binary: $VOLT_VIM when set
+// (for the default Vim flavor), otherwise exec.LookPath on flavor's
+// binary name.
+func Executable(flavor Flavor) (string, error) {
+	if flavor == Vim || flavor == "" {
+		if vim := os.Getenv("VOLT_VIM"); vim != "" {
+			return vim, nil
+		}
+	}
+	name, err := ExecutableName(flavor)
+	if err != nil {
+		return "", err
+	}
+	return exec.LookPath(name)
+}
+
+// VimInfo is the probed version and feature set of a vim-family binary.
+type VimInfo struct {
+	Flavor     Flavor   `json:"flavor"`
+	Path       string   `json:"path"`
+	Version    string   `json:"version"`
+	Features   []string `json:"features"`
+	HasPython3 bool     `json:"has_python3"`
+	HasLua     bool     `json:"has_lua"`
+	APIInfo    string   `json:"api_info,omitempty"`
+}
+
+// HasFeature reports whether info satisfies a vim has()-style
+// predicate such as "nvim-0.9", "python3", or "patch-8.2.3000". This
+// lets volt evaluate plugconf gates like `if has('nvim-0.9')` itself at
+// plan-time.
+func (info *VimInfo) HasFeature(predicate string) bool {
+	predicate = strings.TrimSpace(predicate)
+	switch {
+	case strings.HasPrefix(predicate, "nvim-"):
+		return info.Flavor == Neovim && versionAtLeast(info.Version, strings.TrimPrefix(predicate, "nvim-"))
+	case strings.HasPrefix(predicate, "patch-"):
+		return versionAtLeast(info.Version, strings.TrimPrefix(predicate, "patch-"))
+	}
+	for _, f := range info.Features {
+		if f == "+"+predicate {
+			return true
+		}
+	}
+	return false
+}
+
+func versionAtLeast(have, want string) bool {
+	haveParts := strings.Split(have, ".")
+	wantParts := strings.Split(want, ".")
+	for i := 0; i < len(wantParts); i++ {
+		var h, w int
+		if i < len(haveParts) {
+			h, _ = strconv.Atoi(haveParts[i])
+		}
+		w, _ = strconv.Atoi(wantParts[i])
+		if h != w {
+			return h > w
+		}
+	}
+	return true
+}
+
+const cacheFileName = "vim-info.json"
+
+type cacheEntry struct {
+	Info    VimInfo   `json:"info"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+type cache map[string]cacheEntry
+
+// CurrentVimInfo returns the probed VimInfo for the currently
+// configured vim flavor, probing the binary at most once per
+// (absolute path, mtime) and caching the result at
+// (primary VOLTPATH root)/tmp/vim-info.json.
+func CurrentVimInfo() (*VimInfo, error) {
+	flavor := CurrentFlavor()
+	path, err := Executable(flavor)
+	if err != nil {
+		return nil, err
+	}
+	return cachedProbe(path, flavor)
+}
+
+func cachedProbe(path string, flavor Flavor) (*VimInfo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	fi, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDir := pathutil.TempDir()
+	c := make(cache)
+	// A corrupt or missing cache is not fatal, just start fresh.
+	_ = diskcache.Load(cacheDir, cacheFileName, &c)
+	if entry, ok := c[abs]; ok && entry.ModTime.Equal(fi.ModTime()) {
+		info := entry.Info
+		return &info, nil
+	}
+
+	info, err := ProbeVim(abs)
+	if err != nil {
+		return nil, err
+	}
+	info.Flavor = flavor
+
+	c[abs] = cacheEntry{Info: *info, ModTime: fi.ModTime()}
+	// Caching is best-effort; a write failure shouldn't fail CurrentVimInfo.
+	_ = diskcache.Save(cacheDir, cacheFileName, c)
+	return info, nil
+}
+
+var versionLineRe = regexp.MustCompile(`(?i)(VIM - Vi IMproved|NVIM v)\s*([0-9][0-9.]*)`)
+
+// patchLineRe matches vim's "Included patches: 1-3454" (sometimes a
+// comma-separated list of numbers and ranges) line, which carries the
+// patch level has()'s "patch-8.2.3000" style predicates need but that
+// never appears on the version line itself.
+var patchLineRe = regexp.MustCompile(`(?i)Included patches:\s*(.+)`)
+
+// highestPatch parses a patchLineRe match's capture (e.g. "1-3454", or
+// "1, 3, 5-10") into the highest patch number it lists.
+func highestPatch(list string) int {
+	highest := 0
+	for _, tok := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if i := strings.LastIndex(tok, "-"); i >= 0 {
+			tok = tok[i+1:]
+		}
+		if n, err := strconv.Atoi(tok); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest
+}
+
+// snapshotEnv returns a copy of the current process environment with
+// PATH pinned to its value at call time, so probing is deterministic
+// under test regardless of what else mutates $PATH concurrently,
+// mirroring govim's testdriver PATH-snapshot pattern. Everything else
+// (HOME, SystemRoot, TEMP, ...) passes through untouched, since vim
+// binaries (gvim and nvim --api-info especially) can fail to launch
+// without them.
+func snapshotEnv() []string {
+	path := "PATH=" + os.Getenv("PATH")
+	environ := os.Environ()
+	env := make([]string, 0, len(environ)+1)
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, "PATH=") {
+			env = append(env, kv)
+		}
+	}
+	return append(env, path)
+}
+
+// ProbeVim runs the vim-family binary at path with --version (and, for
+// Neovim, --api-info) under a timeout and a snapshotEnv() environment.
+func ProbeVim(path string) (*VimInfo, error) {
+	env := snapshotEnv()
+
+	out, err := runProbe(path, []string{"--version"}, env)
+	if err != nil {
+		return nil, fmt.Errorf("vimexe: probing %s --version: %w", path, err)
+	}
+
+	info := &VimInfo{Path: path}
+	if m := versionLineRe.FindStringSubmatch(out); m != nil {
+		info.Version = m[2]
+		if pm := patchLineRe.FindStringSubmatch(out); pm != nil {
+			if patch := highestPatch(pm[1]); patch > 0 {
+				info.Version += "." + strconv.Itoa(patch)
+			}
+		}
+	}
+	for _, tok := range strings.Fields(out) {
+		if strings.HasPrefix(tok, "+") {
+			info.Features = append(info.Features, tok)
+			switch tok {
+			case "+python3":
+				info.HasPython3 = true
+			case "+lua":
+				info.HasLua = true
+			}
+		}
+	}
+
+	if strings.Contains(out, "NVIM") {
+		if apiOut, err := runProbe(path, []string{"--api-info"}, env); err == nil {
+			info.APIInfo = apiOut
+		}
+	}
+
+	return info, nil
+}
+
+func runProbe(path string, args, env []string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
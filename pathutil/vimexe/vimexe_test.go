@@ -0,0 +1,114 @@
+package vimexe
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestVersionLineRe(t *testing.T) {
+	tests := []struct {
+		out  string
+		want string
+	}{
+		{"VIM - Vi IMproved 8.2 (2019 Dec 12, compiled Jan 05 2021 22:14:45)\n", "8.2"},
+		{"NVIM v0.9.1\nBuild type: Release\n", "0.9.1"},
+		{"no version here", ""},
+	}
+	for _, tt := range tests {
+		m := versionLineRe.FindStringSubmatch(tt.out)
+		got := ""
+		if m != nil {
+			got = m[2]
+		}
+		if got != tt.want {
+			t.Errorf("versionLineRe.FindStringSubmatch(%q) = %q, want %q", tt.out, got, tt.want)
+		}
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		have, want string
+		atLeast    bool
+	}{
+		{"0.9.1", "0.9", true},
+		{"0.9.0", "0.9", true},
+		{"0.8.3", "0.9", false},
+		{"8.2", "8.1", true},
+		{"8.2", "8.2.1", false},
+	}
+	for _, tt := range tests {
+		if got := versionAtLeast(tt.have, tt.want); got != tt.atLeast {
+			t.Errorf("versionAtLeast(%q, %q) = %v, want %v", tt.have, tt.want, got, tt.atLeast)
+		}
+	}
+}
+
+func TestHighestPatch(t *testing.T) {
+	tests := []struct {
+		list string
+		want int
+	}{
+		{"1-3454", 3454},
+		{"1, 3, 5-10", 10},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := highestPatch(tt.list); got != tt.want {
+			t.Errorf("highestPatch(%q) = %d, want %d", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestPatchLineReAppendsToVersion(t *testing.T) {
+	out := "VIM - Vi IMproved 8.2 (2019 Dec 12, compiled Jan 05 2021 22:14:45)\n" +
+		"Included patches: 1-3454\n"
+	m := versionLineRe.FindStringSubmatch(out)
+	if m == nil {
+		t.Fatal("versionLineRe did not match")
+	}
+	version := m[2]
+	pm := patchLineRe.FindStringSubmatch(out)
+	if pm == nil {
+		t.Fatal("patchLineRe did not match")
+	}
+	if patch := highestPatch(pm[1]); patch > 0 {
+		version += "." + strconv.Itoa(patch)
+	}
+	if want := "8.2.3454"; version != want {
+		t.Errorf("version = %q, want %q", version, want)
+	}
+}
+
+func TestHasFeaturePatch(t *testing.T) {
+	info := &VimInfo{Flavor: Vim, Version: "8.2.3454"}
+	cases := map[string]bool{
+		"patch-8.2.3000": true,
+		"patch-8.2.4000": false,
+		"patch-8.1":      true,
+	}
+	for predicate, want := range cases {
+		if got := info.HasFeature(predicate); got != want {
+			t.Errorf("HasFeature(%q) = %v, want %v", predicate, got, want)
+		}
+	}
+}
+
+func TestHasFeature(t *testing.T) {
+	info := &VimInfo{
+		Flavor:   Neovim,
+		Version:  "0.9.1",
+		Features: []string{"+python3", "-lua"},
+	}
+	cases := map[string]bool{
+		"nvim-0.9":  true,
+		"nvim-0.10": false,
+		"python3":   true,
+		"lua":       false,
+	}
+	for predicate, want := range cases {
+		if got := info.HasFeature(predicate); got != want {
+			t.Errorf("HasFeature(%q) = %v, want %v", predicate, got, want)
+		}
+	}
+}